@@ -0,0 +1,151 @@
+package definition
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// resourceRequest describes one object getTemplateContext needs to resolve
+// against the live cluster, either by name (a direct Get) or by labels (a
+// List shared with every other request against the same GVK).
+type resourceRequest struct {
+	// fieldKey is OutputFieldName or OutputsFieldName, selecting where the
+	// resolved object is written in the template context.
+	fieldKey string
+	// outputName is set when fieldKey is OutputsFieldName.
+	outputName string
+	obj        *unstructured.Unstructured
+	labels     map[string]string
+	// traitResource is the oam.TraitResource label value to disambiguate
+	// multiple objects returned by the same GVK+labels List.
+	traitResource string
+}
+
+// TemplateContextBuilder assembles the template context (the CUE `context`
+// consumed by HealthCheck/Status policy templates) for a Definition's
+// rendered output. Unlike resolving each auxiliary with its own Get/List,
+// Build groups label-selected requests by GVK and issues a single List per
+// GVK, splitting the results in-memory.
+//
+// A TemplateContextBuilder can be pre-warmed with a caching Reader (e.g. a
+// reconcile-scoped snapshot) and reused across the HealthCheck and Status
+// calls of a single reconcile; see SetTemplateContextBuilder.
+type TemplateContextBuilder struct {
+	Reader client.Reader
+}
+
+// NewTemplateContextBuilder creates a TemplateContextBuilder backed by reader.
+func NewTemplateContextBuilder(reader client.Reader) *TemplateContextBuilder {
+	return &TemplateContextBuilder{Reader: reader}
+}
+
+// ContextBuilderSetter lets a controller pre-warm a TemplateContextBuilder
+// (e.g. backed by a cached reader) and have workloadDef/traitDef reuse it
+// instead of constructing one from the client.Client passed to each
+// HealthCheck/Status call.
+type ContextBuilderSetter interface {
+	SetTemplateContextBuilder(b *TemplateContextBuilder)
+}
+
+// labelsKey serializes labels into a stable, comparable string so requests
+// against the same GVK but with different label selectors are never grouped
+// (and List-ed) together.
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+func (b *TemplateContextBuilder) build(ns string, requests []resourceRequest) (map[string]interface{}, error) {
+	type gvkGroup struct {
+		gvk    schema.GroupVersionKind
+		labels map[string]string
+		items  []resourceRequest
+	}
+	groups := map[schema.GroupVersionKind]map[string]*gvkGroup{}
+	var named []resourceRequest
+	for _, r := range requests {
+		if r.obj.GetName() != "" {
+			named = append(named, r)
+			continue
+		}
+		gvk := r.obj.GroupVersionKind()
+		byLabels, ok := groups[gvk]
+		if !ok {
+			byLabels = map[string]*gvkGroup{}
+			groups[gvk] = byLabels
+		}
+		key := labelsKey(r.labels)
+		g, ok := byLabels[key]
+		if !ok {
+			g = &gvkGroup{gvk: gvk, labels: r.labels}
+			byLabels[key] = g
+		}
+		g.items = append(g.items, r)
+	}
+
+	root := map[string]interface{}{}
+	outputs := map[string]interface{}{}
+	assign := func(r resourceRequest, obj map[string]interface{}) {
+		if r.fieldKey == OutputsFieldName {
+			outputs[r.outputName] = obj
+		} else {
+			root[r.fieldKey] = obj
+		}
+	}
+
+	for _, r := range named {
+		u, err := util.GetObjectGivenGVKAndName(context.Background(), b.Reader, r.obj.GroupVersionKind(), ns, r.obj.GetName())
+		if err != nil {
+			return nil, err
+		}
+		assign(r, u.Object)
+	}
+
+	for _, byLabels := range groups {
+		for _, g := range byLabels {
+			list, err := util.GetObjectsGivenGVKAndLabels(context.Background(), b.Reader, g.gvk, ns, g.labels)
+			if err != nil {
+				return nil, err
+			}
+			byTraitResource := map[string]unstructured.Unstructured{}
+			for _, item := range list.Items {
+				byTraitResource[item.GetLabels()[oam.TraitResource]] = item
+			}
+			for _, r := range g.items {
+				obj, ok := byTraitResource[r.traitResource]
+				if !ok && len(list.Items) == 1 {
+					obj, ok = list.Items[0], true
+				}
+				if !ok {
+					return nil, errors.Errorf("no resources found gvk(%v) labels(%v)", g.gvk, g.labels)
+				}
+				assign(r, obj.Object)
+			}
+		}
+	}
+
+	if len(outputs) > 0 {
+		root[OutputsFieldName] = outputs
+	}
+	return root, nil
+}
@@ -0,0 +1,67 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newUnstructuredConfigMap(name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetName(name)
+	u.SetLabels(labels)
+	return u
+}
+
+// TestTemplateContextBuilderBuildGroupsByLabelSelector guards the bug fixed
+// by 7d50f3b: two label-selected requests against the same GVK but with
+// different label selectors must each get their own List, not be merged into
+// a single List keyed only by GVK.
+func TestTemplateContextBuilderBuildGroupsByLabelSelector(t *testing.T) {
+	cmA := newUnstructuredConfigMap("a", map[string]string{"group": "a"})
+	cmB := newUnstructuredConfigMap("b", map[string]string{"group": "b"})
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cmA, cmB).Build()
+
+	b := NewTemplateContextBuilder(cli)
+	requests := []resourceRequest{
+		{
+			fieldKey:   OutputsFieldName,
+			outputName: "a",
+			obj:        newUnstructuredConfigMap("", nil),
+			labels:     map[string]string{"group": "a"},
+		},
+		{
+			fieldKey:   OutputsFieldName,
+			outputName: "b",
+			obj:        newUnstructuredConfigMap("", nil),
+			labels:     map[string]string{"group": "b"},
+		},
+	}
+
+	root, err := b.build("default", requests)
+	require.NoError(t, err)
+
+	outputs, ok := root[OutputsFieldName].(map[string]interface{})
+	require.True(t, ok)
+	gotA, ok := outputs["a"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "a", gotA["metadata"].(map[string]interface{})["name"])
+	gotB, ok := outputs["b"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "b", gotB["metadata"].(map[string]interface{})["name"])
+}
+
+func TestLabelsKeyStableAndDistinguishing(t *testing.T) {
+	assert.Equal(t, labelsKey(map[string]string{"a": "1", "b": "2"}), labelsKey(map[string]string{"b": "2", "a": "1"}))
+	assert.NotEqual(t, labelsKey(map[string]string{"group": "a"}), labelsKey(map[string]string{"group": "b"}))
+}
@@ -0,0 +1,202 @@
+package definition
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"cuelang.org/go/cue"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// FieldDiff is one differing field between a live object and its rendered
+// (desired) counterpart. Before/After are nil when the field is being added
+// or removed rather than changed.
+type FieldDiff struct {
+	Before interface{}
+	After  interface{}
+}
+
+// ResourceDiff is a structured diff of one rendered object against its live
+// cluster counterpart, used by `vela`'s dry-run preview (see AbstractEngine.Diff).
+type ResourceDiff struct {
+	GVK  schema.GroupVersionKind
+	Name string
+	// Added is true when the object doesn't exist on the cluster yet.
+	Added bool
+	// Changed maps a dotted field path to its before/after value; empty when
+	// Added is true.
+	Changed map[string]FieldDiff
+}
+
+// renderContext captures what Complete would normally write into the real
+// process.Context (SetBase/PutAuxiliaries), without applying it, so Render
+// can produce a preview with no side effects. BaseContextFile/BaseContextLabels
+// and anything else still pass through to the wrapped ctx.
+type renderContext struct {
+	process.Context
+	base        model.Base
+	auxiliaries []process.Auxiliary
+}
+
+func (r *renderContext) SetBase(base model.Base) {
+	r.base = base
+}
+
+func (r *renderContext) PutAuxiliaries(auxiliary process.Auxiliary) {
+	r.auxiliaries = append(r.auxiliaries, auxiliary)
+}
+
+func (r *renderContext) Output() (model.Base, []process.Auxiliary) {
+	live, auxiliaries := r.Context.Output()
+	if r.base == nil && live != nil {
+		// No SetBase call has happened on the shadow yet (e.g. a trait's
+		// patch step runs before its own output, or a trait template has no
+		// output of its own), so clone the wrapped ctx's already-accumulated
+		// base and cache it on the shadow. A patch step unifies directly into
+		// whatever Output returns, so handing out the live base here would
+		// let a preview's patch mutate the real, shared object ctx is
+		// backing instead of the shadow's own copy.
+		if clone, err := cloneBase(live); err == nil {
+			r.base = clone
+		} else {
+			return live, append(auxiliaries, r.auxiliaries...)
+		}
+	}
+	// r.base is still nil here when the wrapped ctx never had SetBase called
+	// on it either (e.g. Render/Diff called directly on a trait whose
+	// underlying workload was never Complete-d against this ctx); there is
+	// nothing to clone, so fall through and hand back the nil base as-is
+	// rather than dereferencing it.
+	return r.base, append(auxiliaries, r.auxiliaries...)
+}
+
+// cloneBase rebuilds an independent model.Base with the same content as
+// base, so a caller can unify a patch into the clone without mutating base
+// itself. base must not be nil.
+func cloneBase(base model.Base) (model.Base, error) {
+	u, err := base.Unstructured()
+	if err != nil {
+		return nil, err
+	}
+	bt, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, err
+	}
+	var r cue.Runtime
+	inst, err := r.Compile("-", string(bt))
+	if err != nil {
+		return nil, err
+	}
+	return model.NewBase(inst.Value())
+}
+
+func (r *renderContext) unstructured() ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	if r.base != nil {
+		u, err := r.base.Unstructured()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, u)
+	}
+	for _, aux := range r.auxiliaries {
+		u, err := aux.Ins.Unstructured()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, u)
+	}
+	return objs, nil
+}
+
+// renderViaComplete runs engine.Complete against a throwaway renderContext
+// wrapping ctx, so it sees the same BaseContextFile/BaseContextLabels as a
+// real reconcile but nothing is written back into ctx.
+func renderViaComplete(engine AbstractEngine, ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	shadow := &renderContext{Context: ctx}
+	if err := engine.Complete(shadow, abstractTemplate); err != nil {
+		return nil, err
+	}
+	return shadow.unstructured()
+}
+
+// diffViaRender renders abstractTemplate with engine and diffs each rendered
+// object against its live cluster counterpart, matched by GVK+namespace+name.
+// An object with no name has no stable identity to fetch by, so it is always
+// reported as Added.
+func diffViaRender(engine AbstractEngine, ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	rendered, err := renderViaComplete(engine, ctx, abstractTemplate)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]ResourceDiff, 0, len(rendered))
+	for _, desired := range rendered {
+		gvk := desired.GroupVersionKind()
+		d := ResourceDiff{GVK: gvk, Name: desired.GetName()}
+		if d.Name == "" {
+			d.Added = true
+			diffs = append(diffs, d)
+			continue
+		}
+		live, err := util.GetObjectGivenGVKAndName(context.Background(), cli, gvk, ns, d.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				d.Added = true
+				diffs = append(diffs, d)
+				continue
+			}
+			return nil, err
+		}
+		d.Changed = diffFields(live.Object, desired.Object)
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+func diffFields(live, desired map[string]interface{}) map[string]FieldDiff {
+	out := map[string]FieldDiff{}
+	walkDiff("", live, desired, out)
+	return out
+}
+
+func walkDiff(prefix string, live, desired interface{}, out map[string]FieldDiff) {
+	liveMap, liveIsMap := live.(map[string]interface{})
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	if liveIsMap && desiredIsMap {
+		seen := map[string]struct{}{}
+		for k := range liveMap {
+			seen[k] = struct{}{}
+		}
+		for k := range desiredMap {
+			seen[k] = struct{}{}
+		}
+		for k := range seen {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			lv, lok := liveMap[k]
+			dv, dok := desiredMap[k]
+			switch {
+			case lok && dok:
+				walkDiff(path, lv, dv, out)
+			case lok && !dok:
+				out[path] = FieldDiff{Before: lv}
+			case !lok && dok:
+				out[path] = FieldDiff{After: dv}
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(live, desired) {
+		out[prefix] = FieldDiff{Before: live, After: desired}
+	}
+}
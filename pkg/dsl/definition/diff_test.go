@@ -0,0 +1,115 @@
+package definition
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+func TestWalkDiff(t *testing.T) {
+	live := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(1),
+			"keep":     "same",
+		},
+		"removed": "gone",
+	}
+	desired := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"keep":     "same",
+		},
+		"added": "new",
+	}
+
+	diffs := diffFields(live, desired)
+
+	assert.Equal(t, FieldDiff{Before: float64(1), After: float64(2)}, diffs["spec.replicas"])
+	assert.Equal(t, FieldDiff{Before: "gone"}, diffs["removed"])
+	assert.Equal(t, FieldDiff{After: "new"}, diffs["added"])
+	_, unchanged := diffs["spec.keep"]
+	assert.False(t, unchanged, "an unchanged field must not be reported")
+}
+
+// fakeContext is a minimal process.Context standing in for the one Complete
+// is normally called with, pre-loaded with a base the way workloadDef.Complete
+// would leave it by the time a trait's patch step runs against it.
+type fakeContext struct {
+	base model.Base
+}
+
+func (f *fakeContext) BaseContextFile() string                   { return "" }
+func (f *fakeContext) BaseContextLabels() map[string]string      { return nil }
+func (f *fakeContext) SetBase(base model.Base)                   { f.base = base }
+func (f *fakeContext) PutAuxiliaries(_ process.Auxiliary)         {}
+func (f *fakeContext) Output() (model.Base, []process.Auxiliary) { return f.base, nil }
+
+func mustBase(t *testing.T, doc string) model.Base {
+	t.Helper()
+	var r cue.Runtime
+	inst, err := r.Compile("-", doc)
+	require.NoError(t, err)
+	base, err := model.NewBase(inst.Value())
+	require.NoError(t, err)
+	return base
+}
+
+func mustOther(t *testing.T, doc string) model.Other {
+	t.Helper()
+	var r cue.Runtime
+	inst, err := r.Compile("-", doc)
+	require.NoError(t, err)
+	other, err := model.NewOther(inst.Value())
+	require.NoError(t, err)
+	return other
+}
+
+func replicas(t *testing.T, base model.Base) int64 {
+	t.Helper()
+	u, err := base.Unstructured()
+	require.NoError(t, err)
+	v, _, err := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	require.NoError(t, err)
+	return v
+}
+
+// TestRenderContextOutputClonesSharedBase guards the exact leak a
+// trait's patch step (traitDef.Complete, applyTraitManifests) would hit
+// against a renderContext whose shadow never got its own SetBase call: since
+// Unify mutates its receiver in place, handing out the wrapped ctx's live
+// base from Output() would let a Render/Diff preview's patch step mutate the
+// real, shared object the reconcile depends on.
+func TestRenderContextOutputClonesSharedBase(t *testing.T) {
+	live := &fakeContext{base: mustBase(t, `
+apiVersion: "apps/v1"
+kind:       "Deployment"
+spec: replicas: 1
+`)}
+	shadow := &renderContext{Context: live}
+
+	base, _ := shadow.Output()
+	require.NoError(t, base.Unify(mustOther(t, `spec: replicas: 2`)))
+
+	assert.EqualValues(t, 1, replicas(t, live.base), "patching the shadow's Output() must not mutate ctx's live base")
+	assert.EqualValues(t, 2, replicas(t, base), "the shadow's own Output() must reflect its patch")
+}
+
+// TestRenderContextOutputNilBaseDoesNotPanic covers Render/Diff called
+// directly on a standalone trait (e.g. previewing a patch-only trait without
+// first completing its workload into the same ctx), where the wrapped ctx's
+// Output() has never had SetBase called on it at all. Output() must hand
+// back the nil base as-is instead of panicking inside cloneBase.
+func TestRenderContextOutputNilBaseDoesNotPanic(t *testing.T) {
+	shadow := &renderContext{Context: &fakeContext{}}
+
+	assert.NotPanics(t, func() {
+		base, _ := shadow.Output()
+		assert.Nil(t, base)
+	})
+}
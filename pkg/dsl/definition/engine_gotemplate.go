@@ -0,0 +1,97 @@
+package definition
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+func init() {
+	RegisterEngine(engineKey(workloadKind, GoTemplate), func(name string) AbstractEngine {
+		return &goTemplateWorkloadDef{workloadDef: workloadDef{def: def{name: name}}}
+	})
+	RegisterEngine(engineKey(traitKind, GoTemplate), func(name string) AbstractEngine {
+		return &goTemplateTraitDef{traitDef: traitDef{def: def{name: name}}}
+	})
+}
+
+// renderGoTemplate renders abstractTemplate with the stdlib text/template
+// engine, exposing `parameter` and `context` in scope as `.Parameter` and
+// `.Context`.
+func renderGoTemplate(name, abstractTemplate string, params interface{}, ctx process.Context) ([]byte, error) {
+	tmpl, err := template.New(name).Parse(abstractTemplate)
+	if err != nil {
+		return nil, errors.WithMessage(err, "parse go-template")
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Parameter": params,
+		"Context":   ctx.BaseContextLabels(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, errors.WithMessage(err, "render go-template")
+	}
+	return buf.Bytes(), nil
+}
+
+// goTemplateWorkloadDef renders a WorkloadDefinition's abstract template with
+// text/template. HealthCheck/Status/getTemplateContext are inherited
+// unchanged from workloadDef.
+type goTemplateWorkloadDef struct {
+	workloadDef
+}
+
+// Complete do workload definition's rendering via the go-template engine
+func (wd *goTemplateWorkloadDef) Complete(ctx process.Context, abstractTemplate string) error {
+	if err := wd.checkTemplatePresent(abstractTemplate); err != nil {
+		return err
+	}
+	rendered, err := renderGoTemplate(wd.name, abstractTemplate, wd.params, ctx)
+	if err != nil {
+		return errors.WithMessagef(err, "workloadDef %s eval", wd.name)
+	}
+	return applyWorkloadManifests(ctx, wd.name, rendered)
+}
+
+// Render renders the workload's abstract template without mutating ctx
+func (wd *goTemplateWorkloadDef) Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	return renderViaComplete(wd, ctx, abstractTemplate)
+}
+
+// Diff renders the workload's abstract template and diffs it against the cluster
+func (wd *goTemplateWorkloadDef) Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	return diffViaRender(wd, ctx, cli, ns, abstractTemplate)
+}
+
+// goTemplateTraitDef renders a TraitDefinition's abstract template with
+// text/template.
+type goTemplateTraitDef struct {
+	traitDef
+}
+
+// Complete do trait definition's rendering via the go-template engine
+func (td *goTemplateTraitDef) Complete(ctx process.Context, abstractTemplate string) error {
+	if err := td.checkTemplatePresent(abstractTemplate); err != nil {
+		return err
+	}
+	rendered, err := renderGoTemplate(td.name, abstractTemplate, td.params, ctx)
+	if err != nil {
+		return errors.WithMessagef(err, "traitDef %s build", td.name)
+	}
+	return applyTraitManifests(ctx, td.name, rendered)
+}
+
+// Render renders the trait's abstract template without mutating ctx
+func (td *goTemplateTraitDef) Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	return renderViaComplete(td, ctx, abstractTemplate)
+}
+
+// Diff renders the trait's abstract template and diffs it against the cluster
+func (td *goTemplateTraitDef) Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	return diffViaRender(td, ctx, cli, ns, abstractTemplate)
+}
@@ -0,0 +1,94 @@
+package definition
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+func init() {
+	RegisterEngine(engineKey(workloadKind, HelmTemplate), func(name string) AbstractEngine {
+		return &helmWorkloadDef{workloadDef: workloadDef{def: def{name: name}}}
+	})
+	RegisterEngine(engineKey(traitKind, HelmTemplate), func(name string) AbstractEngine {
+		return &helmTraitDef{traitDef: traitDef{def: def{name: name}}}
+	})
+}
+
+// renderHelmTemplate renders abstractTemplate as a Helm chart template, with
+// `parameter` exposed as chart Values using the usual Sprig function set.
+func renderHelmTemplate(name, abstractTemplate string, params interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(sprig.TxtFuncMap()).Parse(abstractTemplate)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "parse helm chart template")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{"Values": params}); err != nil {
+		return nil, errors.WithMessagef(err, "render helm chart template")
+	}
+	return buf.Bytes(), nil
+}
+
+// helmWorkloadDef renders a WorkloadDefinition's abstract template as a Helm
+// chart template. HealthCheck/Status/getTemplateContext are inherited
+// unchanged from workloadDef since they operate on the already-rendered
+// output, not on the template engine.
+type helmWorkloadDef struct {
+	workloadDef
+}
+
+// Complete do workload definition's rendering via the Helm engine
+func (wd *helmWorkloadDef) Complete(ctx process.Context, abstractTemplate string) error {
+	if err := wd.checkTemplatePresent(abstractTemplate); err != nil {
+		return err
+	}
+	rendered, err := renderHelmTemplate(wd.name, abstractTemplate, wd.params)
+	if err != nil {
+		return errors.WithMessagef(err, "workloadDef %s eval", wd.name)
+	}
+	return applyWorkloadManifests(ctx, wd.name, rendered)
+}
+
+// Render renders the workload's abstract template without mutating ctx
+func (wd *helmWorkloadDef) Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	return renderViaComplete(wd, ctx, abstractTemplate)
+}
+
+// Diff renders the workload's abstract template and diffs it against the cluster
+func (wd *helmWorkloadDef) Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	return diffViaRender(wd, ctx, cli, ns, abstractTemplate)
+}
+
+// helmTraitDef renders a TraitDefinition's abstract template as a Helm chart
+// template.
+type helmTraitDef struct {
+	traitDef
+}
+
+// Complete do trait definition's rendering via the Helm engine
+func (td *helmTraitDef) Complete(ctx process.Context, abstractTemplate string) error {
+	if err := td.checkTemplatePresent(abstractTemplate); err != nil {
+		return err
+	}
+	rendered, err := renderHelmTemplate(td.name, abstractTemplate, td.params)
+	if err != nil {
+		return errors.WithMessagef(err, "traitDef %s build", td.name)
+	}
+	return applyTraitManifests(ctx, td.name, rendered)
+}
+
+// Render renders the trait's abstract template without mutating ctx
+func (td *helmTraitDef) Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	return renderViaComplete(td, ctx, abstractTemplate)
+}
+
+// Diff renders the trait's abstract template and diffs it against the cluster
+func (td *helmTraitDef) Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	return diffViaRender(td, ctx, cli, ns, abstractTemplate)
+}
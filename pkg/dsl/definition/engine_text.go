@@ -0,0 +1,210 @@
+package definition
+
+import (
+	"bufio"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+// Non-CUE engines (helm, go-template) render plain YAML/JSON text instead of
+// a CUE struct, so they can't rely on field names like `output`/`outputs` to
+// tell documents apart. Instead, each rendered document is expected to start
+// with a marker comment using the same field names, e.g.:
+//
+//	# output
+//	apiVersion: apps/v1
+//	kind: Deployment
+//	---
+//	# outputs.service
+//	apiVersion: v1
+//	kind: Service
+//	---
+//	# patch
+//	spec:
+//	  replicas: 2
+//
+// A document with no marker is treated as `output`.
+const (
+	outputMarker  = "# " + OutputFieldName
+	outputsMarker = "# " + OutputsFieldName + "."
+	patchMarker   = "# " + PatchFieldName
+)
+
+// renderedDocs splits a multi-document YAML/JSON manifest produced by a
+// non-CUE engine into its output/outputs/patch documents.
+type renderedDocs struct {
+	output  []byte
+	outputs map[string][]byte
+	patch   []byte
+}
+
+func splitRenderedManifests(rendered []byte) (renderedDocs, error) {
+	var docs renderedDocs
+	docs.outputs = map[string][]byte{}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(rendered)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var marker, name string
+	var body strings.Builder
+	var sawUnmarkedOutput bool
+	var flushErr error
+	flush := func() {
+		doc := body.String()
+		switch {
+		case marker == outputsMarker && name != "":
+			docs.outputs[name] = []byte(doc)
+		case marker == patchMarker:
+			docs.patch = []byte(doc)
+		case strings.TrimSpace(doc) == "":
+			// a blank document, e.g. a trailing "---" with nothing after it
+		case marker == outputMarker:
+			docs.output = []byte(doc)
+		default:
+			// no marker comment: the standard Helm convention of plain
+			// "---"-separated multi-doc YAML. A single unmarked document is
+			// accepted as `output`; a second one has no way to tell which
+			// object the caller meant and would otherwise silently overwrite
+			// the first, so treat it as an error instead of dropping data.
+			if sawUnmarkedOutput {
+				flushErr = errors.Errorf("rendered manifest has more than one document with no %q/%q/%q marker comment; use exactly one unmarked document for the main output, or mark the rest", outputMarker, outputsMarker+"<name>", patchMarker)
+				break
+			}
+			sawUnmarkedOutput = true
+			docs.output = []byte(doc)
+		}
+		marker, name = "", ""
+		body.Reset()
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "---":
+			flush()
+			continue
+		case strings.HasPrefix(strings.TrimSpace(line), outputsMarker):
+			marker = outputsMarker
+			name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), outputsMarker))
+			continue
+		case strings.TrimSpace(line) == outputMarker:
+			marker = outputMarker
+			continue
+		case strings.TrimSpace(line) == patchMarker:
+			marker = patchMarker
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	flush()
+	if flushErr != nil {
+		return docs, flushErr
+	}
+	if err := scanner.Err(); err != nil {
+		return docs, errors.WithMessage(err, "scan rendered manifest")
+	}
+	return docs, nil
+}
+
+// cueValueFromYAML decodes a YAML/JSON document into a cue.Value so it can be
+// fed into model.NewBase/model.NewOther the same way the CUE engine does.
+func cueValueFromYAML(doc []byte) (cue.Value, error) {
+	bt, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		return cue.Value{}, errors.WithMessage(err, "convert rendered manifest to json")
+	}
+	var r cue.Runtime
+	inst, err := r.Compile("-", string(bt))
+	if err != nil {
+		return cue.Value{}, errors.WithMessage(err, "compile rendered manifest")
+	}
+	return inst.Value(), nil
+}
+
+// applyWorkloadManifests turns rendered manifests into the workload's base
+// and AuxiliaryWorkload auxiliaries, the same roles the CUE engine fills from
+// `output`/`outputs`.
+func applyWorkloadManifests(ctx process.Context, name string, rendered []byte) error {
+	docs, err := splitRenderedManifests(rendered)
+	if err != nil {
+		return err
+	}
+	if len(docs.output) == 0 {
+		return errors.Errorf("workloadDef %s: rendered manifest has no %q document (and no single unmarked document to use as one)", name, outputMarker)
+	}
+	v, err := cueValueFromYAML(docs.output)
+	if err != nil {
+		return errors.WithMessagef(err, "workloadDef %s output", name)
+	}
+	base, err := model.NewBase(v)
+	if err != nil {
+		return errors.WithMessagef(err, "workloadDef %s new base", name)
+	}
+	ctx.SetBase(base)
+	for outName, doc := range docs.outputs {
+		v, err := cueValueFromYAML(doc)
+		if err != nil {
+			return errors.WithMessagef(err, "workloadDef %s outputs(%s)", name, outName)
+		}
+		other, err := model.NewOther(v)
+		if err != nil {
+			return errors.WithMessagef(err, "parse WorkloadDefinition %s outputs(%s)", name, outName)
+		}
+		ctx.PutAuxiliaries(process.Auxiliary{Ins: other, Type: AuxiliaryWorkload, Name: outName, IsOutputs: true})
+	}
+	return nil
+}
+
+// applyTraitManifests turns rendered manifests into the trait's own
+// output/outputs auxiliaries and, if present, unifies a patch document into
+// the current base, mirroring traitDef.Complete's CUE handling.
+func applyTraitManifests(ctx process.Context, name string, rendered []byte) error {
+	docs, err := splitRenderedManifests(rendered)
+	if err != nil {
+		return err
+	}
+	if len(docs.output) > 0 {
+		v, err := cueValueFromYAML(docs.output)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s output", name)
+		}
+		other, err := model.NewOther(v)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s new Assist", name)
+		}
+		ctx.PutAuxiliaries(process.Auxiliary{Ins: other, Type: name, IsOutputs: false})
+	}
+	for outName, doc := range docs.outputs {
+		v, err := cueValueFromYAML(doc)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s outputs(%s)", name, outName)
+		}
+		other, err := model.NewOther(v)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s new Assists(%s)", name, outName)
+		}
+		ctx.PutAuxiliaries(process.Auxiliary{Ins: other, Type: name, Name: outName, IsOutputs: true})
+	}
+	if len(docs.patch) > 0 {
+		v, err := cueValueFromYAML(docs.patch)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s patch", name)
+		}
+		base, _ := ctx.Output()
+		p, err := model.NewOther(v)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s patcher NewOther", name)
+		}
+		if err := base.Unify(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
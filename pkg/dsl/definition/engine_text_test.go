@@ -0,0 +1,77 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRenderedManifests(t *testing.T) {
+	cases := map[string]struct {
+		rendered    string
+		wantOutput  string
+		wantOutputs map[string]string
+		wantPatch   string
+		wantErr     string
+	}{
+		"single unmarked document is output": {
+			rendered:   "kind: Deployment\n",
+			wantOutput: "kind: Deployment\n",
+		},
+		"marked output/outputs/patch": {
+			rendered: "# output\n" +
+				"kind: Deployment\n" +
+				"---\n" +
+				"# outputs.svc\n" +
+				"kind: Service\n" +
+				"---\n" +
+				"# patch\n" +
+				"spec:\n  replicas: 2\n",
+			wantOutput:  "kind: Deployment\n",
+			wantOutputs: map[string]string{"svc": "kind: Service\n"},
+			wantPatch:   "spec:\n  replicas: 2\n",
+		},
+		"blank document between separators is skipped": {
+			rendered: "kind: Deployment\n" +
+				"---\n" +
+				"\n",
+			wantOutput: "kind: Deployment\n",
+		},
+		"second unmarked document is an error": {
+			rendered: "kind: Deployment\n" +
+				"---\n" +
+				"kind: Service\n",
+			wantErr: "more than one document",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			docs, err := splitRenderedManifests([]byte(tc.rendered))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantOutput, string(docs.output))
+			assert.Equal(t, tc.wantPatch, string(docs.patch))
+			gotOutputs := map[string]string{}
+			for k, v := range docs.outputs {
+				gotOutputs[k] = string(v)
+			}
+			if tc.wantOutputs == nil {
+				assert.Empty(t, gotOutputs)
+			} else {
+				assert.Equal(t, tc.wantOutputs, gotOutputs)
+			}
+		})
+	}
+}
+
+func TestApplyWorkloadManifestsErrorsWithoutOutput(t *testing.T) {
+	err := applyWorkloadManifests(&fakeContext{}, "my-workload", []byte("# outputs.svc\nkind: Service\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), outputMarker)
+}
@@ -0,0 +1,42 @@
+package definition
+
+import "fmt"
+
+// HealthStatus is the tri-state result of AbstractEngine.HealthCheck. Unknown
+// lets a reconciler distinguish "not evaluated" (e.g. the Definition is
+// missing) from a definite healthy/unhealthy verdict, instead of defaulting
+// an unresolved check to either bool value.
+type HealthStatus int
+
+const (
+	// HealthStatusUnknown means the health policy could not be evaluated,
+	// typically because the Definition is missing (see ErrDefinitionMissing).
+	HealthStatusUnknown HealthStatus = iota
+	// HealthStatusHealthy means the health policy evaluated true.
+	HealthStatusHealthy
+	// HealthStatusUnhealthy means the health policy evaluated false.
+	HealthStatusUnhealthy
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthStatusHealthy:
+		return "Healthy"
+	case HealthStatusUnhealthy:
+		return "Unhealthy"
+	default:
+		return "Unknown"
+	}
+}
+
+// ErrDefinitionMissing is returned by Complete/HealthCheck when a
+// WorkloadDefinition/TraitDefinition has no abstract template. Following
+// upstream's non-blocking behavior, controllers should treat it as a warning
+// on the affected trait/workload rather than aborting the reconcile.
+type ErrDefinitionMissing struct {
+	Name string
+}
+
+func (e *ErrDefinitionMissing) Error() string {
+	return fmt.Sprintf("definition %s not registered", e.Name)
+}
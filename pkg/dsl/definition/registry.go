@@ -0,0 +1,79 @@
+package definition
+
+// TemplateType identifies which rendering engine a Definition's abstract
+// template is written in. It is read from the `templateType` field on a
+// WorkloadDefinition/TraitDefinition, defaulting to CUETemplate when unset.
+type TemplateType string
+
+const (
+	// CUETemplate renders the abstract template as CUE, the original and
+	// default engine.
+	CUETemplate TemplateType = "cue"
+	// HelmTemplate renders the abstract template as a Helm chart template,
+	// with `parameter` exposed as chart values.
+	HelmTemplate TemplateType = "helm"
+	// GoTemplate renders the abstract template with text/template, with
+	// `parameter` and `context` in scope.
+	GoTemplate TemplateType = "go-template"
+)
+
+// EngineFactory builds a new AbstractEngine for the Definition with the given
+// name.
+type EngineFactory func(name string) AbstractEngine
+
+// engines holds the registered factories, keyed by a combination of the
+// Definition kind (workload/trait) and the template type, since the two
+// kinds need different AbstractEngine implementations for the same engine.
+var engines = map[string]EngineFactory{}
+
+// RegisterEngine registers factory under name so it can later be selected by
+// a Definition's `templateType` field. Engines are expected to register
+// themselves from an init() in their own file, once per Definition kind, e.g.
+// RegisterEngine(engineKey(workloadKind, HelmTemplate), newHelmWorkloadDef).
+func RegisterEngine(name string, factory EngineFactory) {
+	engines[name] = factory
+}
+
+const (
+	workloadKind = "workload"
+	traitKind    = "trait"
+)
+
+func engineKey(kind string, templateType TemplateType) string {
+	return kind + "/" + string(templateType)
+}
+
+func init() {
+	RegisterEngine(engineKey(workloadKind, CUETemplate), func(name string) AbstractEngine {
+		return &workloadDef{def: def{name: name}}
+	})
+	RegisterEngine(engineKey(traitKind, CUETemplate), func(name string) AbstractEngine {
+		return &traitDef{def: def{name: name}}
+	})
+}
+
+// NewWorkloadAbstractEngine creates the Workload Definition AbstractEngine
+// registered for templateType, defaulting to the CUE engine when
+// templateType is empty or unregistered.
+func NewWorkloadAbstractEngine(templateType TemplateType, name string) AbstractEngine {
+	if templateType == "" {
+		templateType = CUETemplate
+	}
+	if factory, ok := engines[engineKey(workloadKind, templateType)]; ok {
+		return factory(name)
+	}
+	return engines[engineKey(workloadKind, CUETemplate)](name)
+}
+
+// NewTraitAbstractEngine creates the Trait Definition AbstractEngine
+// registered for templateType, defaulting to the CUE engine when
+// templateType is empty or unregistered.
+func NewTraitAbstractEngine(templateType TemplateType, name string) AbstractEngine {
+	if templateType == "" {
+		templateType = CUETemplate
+	}
+	if factory, ok := engines[engineKey(traitKind, templateType)]; ok {
+		return factory(name)
+	}
+	return engines[engineKey(traitKind, CUETemplate)](name)
+}
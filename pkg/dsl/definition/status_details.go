@@ -0,0 +1,53 @@
+package definition
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+// StatusDetails is the structured result of evaluating a trait's health
+// policy and custom status templates together. The reconciler writes it into
+// the trait's WorkloadTrait.Message so users see per-trait CUE-evaluated
+// diagnostics on the AppConfig status without inspecting logs.
+type StatusDetails struct {
+	Healthy bool
+	Message string
+	Reason  string
+}
+
+// StatusWithDetails evaluates healthPolicyTemplate and customStatusTemplate
+// against a single template context fetch and returns them as a StatusDetails,
+// so callers get both the health bool and the human-readable message/reason
+// in one call instead of calling HealthCheck and Status separately.
+func (td *traitDef) StatusWithDetails(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate, customStatusTemplate string) (StatusDetails, error) {
+	if td.skipped {
+		return StatusDetails{}, &ErrDefinitionMissing{Name: td.name}
+	}
+	if healthPolicyTemplate == "" && customStatusTemplate == "" {
+		return StatusDetails{Healthy: true}, nil
+	}
+	templateContext, err := td.getTemplateContext(ctx, cli, ns)
+	if err != nil {
+		return StatusDetails{}, errors.WithMessage(err, "get template context")
+	}
+
+	details := StatusDetails{Healthy: true}
+	if healthPolicyTemplate != "" {
+		healthy, reason, err := evalHealthPolicy(templateContext, healthPolicyTemplate)
+		if err != nil {
+			return StatusDetails{}, errors.WithMessage(err, "evaluate health policy")
+		}
+		details.Healthy = healthy
+		details.Reason = reason
+	}
+	if customStatusTemplate != "" {
+		message, err := getStatusMessage(templateContext, customStatusTemplate)
+		if err != nil {
+			return StatusDetails{}, errors.WithMessage(err, "evaluate status message")
+		}
+		details.Message = message
+	}
+	return details, nil
+}
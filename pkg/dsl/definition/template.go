@@ -1,9 +1,9 @@
 package definition
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/build"
@@ -29,6 +29,9 @@ const (
 	CustomMessage = "message"
 	// HealthCheckPolicy defines the health check policy in definition template
 	HealthCheckPolicy = "isHealth"
+	// HealthCheckReason defines the optional human-readable reason a
+	// HealthCheckPolicy evaluated unhealthy, surfaced via StatusDetails.Reason
+	HealthCheckReason = "reason"
 )
 
 const (
@@ -37,31 +40,86 @@ const (
 	AuxiliaryWorkload = "AuxiliaryWorkload"
 )
 
-// AbstractEngine defines Definition's Render interface
+// AbstractEngine defines Definition's Render interface. A Definition selects
+// its implementation by `templateType` (see TemplateType), defaulting to the
+// CUE engine; see RegisterEngine for plugging in additional engines.
 type AbstractEngine interface {
 	Params(params interface{}) AbstractEngine
 	Complete(ctx process.Context, abstractTemplate string) error
-	HealthCheck(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate string) (bool, error)
+	HealthCheck(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate string) (HealthStatus, error)
 	Status(ctx process.Context, cli client.Client, ns string, customStatusTemplate string) (string, error)
+	// Render renders abstractTemplate the same way Complete does, but returns
+	// the base + auxiliary objects directly instead of writing them into ctx,
+	// so a caller can preview them without mutating the real context.
+	Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error)
+	// Diff renders abstractTemplate and compares each rendered object against
+	// its live cluster counterpart, for a `vela` dry-run preview.
+	Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error)
 }
 
 type def struct {
 	name   string
 	params interface{}
+
+	// contextBuilder, when set via SetTemplateContextBuilder, is reused for
+	// every getTemplateContext call instead of building a fresh one from the
+	// cli.Client passed to HealthCheck/Status.
+	contextBuilder *TemplateContextBuilder
+
+	// skipped is set by checkTemplatePresent when Complete was called with no
+	// abstract template, so later HealthCheck/Status calls can short-circuit
+	// into HealthStatusUnknown instead of failing to render a non-existent
+	// template.
+	skipped bool
 }
 
-type workloadDef struct {
-	def
+// SetTemplateContextBuilder implements ContextBuilderSetter.
+func (d *def) SetTemplateContextBuilder(b *TemplateContextBuilder) {
+	d.contextBuilder = b
 }
 
-// NewWorkloadAbstractEngine create Workload Definition AbstractEngine
-func NewWorkloadAbstractEngine(name string) AbstractEngine {
-	return &workloadDef{
-		def: def{
-			name:   name,
-			params: nil,
-		},
+func (d *def) getContextBuilder(cli client.Reader) *TemplateContextBuilder {
+	if d.contextBuilder != nil {
+		return d.contextBuilder
 	}
+	return NewTemplateContextBuilder(cli)
+}
+
+// readerProvider is an optional process.Context capability that gives the
+// `processing` task pipeline (see pkg/dsl/task) access to a client.Reader for
+// tasks like kubeGet/secretGet that need to read the live cluster while
+// rendering. A process.Context that never touches the cluster can leave it
+// unimplemented; processing tasks that need a reader will simply fail.
+//
+// NOTE: no process.Context implementation in this codebase implements
+// readerProvider yet, so kubeGet/secretGet currently fail with "requires a
+// cluster reader" on every real reconcile; wiring a Reader() method through
+// the controller's process.Context is required before either task is usable
+// end-to-end.
+type readerProvider interface {
+	Reader() client.Reader
+}
+
+func processingReader(ctx process.Context) client.Reader {
+	if rp, ok := ctx.(readerProvider); ok {
+		return rp.Reader()
+	}
+	return nil
+}
+
+// checkTemplatePresent distinguishes a missing/empty template (a degraded
+// mode the controller should only warn about) from an invalid one (a real
+// rendering error). Call it first thing in Complete.
+func (d *def) checkTemplatePresent(abstractTemplate string) error {
+	if strings.TrimSpace(abstractTemplate) == "" {
+		d.skipped = true
+		return &ErrDefinitionMissing{Name: d.name}
+	}
+	return nil
+}
+
+type workloadDef struct {
+	def
 }
 
 // Params set definition's params
@@ -72,6 +130,9 @@ func (wd *workloadDef) Params(params interface{}) AbstractEngine {
 
 // Complete do workload definition's rendering
 func (wd *workloadDef) Complete(ctx process.Context, abstractTemplate string) error {
+	if err := wd.checkTemplatePresent(abstractTemplate); err != nil {
+		return err
+	}
 	bi := build.NewContext().NewInstance("", nil)
 	if err := bi.AddFile("-", abstractTemplate); err != nil {
 		return err
@@ -91,6 +152,15 @@ func (wd *workloadDef) Complete(ctx process.Context, abstractTemplate string) er
 		if err := inst.Value().Err(); err != nil {
 			return errors.WithMessagef(err, "workloadDef %s eval", wd.name)
 		}
+
+		processing := inst.Lookup("processing")
+		var err error
+		if processing.Exists() {
+			if inst, err = task.Process(inst, processingReader(ctx)); err != nil {
+				return errors.WithMessagef(err, "workloadDef %s eval", wd.name)
+			}
+		}
+
 		output := inst.Lookup(OutputFieldName)
 		base, err := model.NewBase(output)
 		if err != nil {
@@ -119,11 +189,10 @@ func (wd *workloadDef) Complete(ctx process.Context, abstractTemplate string) er
 }
 
 func (wd *workloadDef) getTemplateContext(ctx process.Context, cli client.Reader, ns string) (map[string]interface{}, error) {
-
 	var commonLabels = map[string]string{}
-	var root = map[string]interface{}{}
+	var rootLabels = map[string]interface{}{}
 	for k, v := range ctx.BaseContextLabels() {
-		root[k] = v
+		rootLabels[k] = v
 		switch k {
 		case "appName":
 			commonLabels[oam.LabelAppName] = v
@@ -138,13 +207,13 @@ func (wd *workloadDef) getTemplateContext(ctx process.Context, cli client.Reader
 		return nil, err
 	}
 	// workload main resource will have a unique label("app.oam.dev/resourceType"="WORKLOAD") in per component/app level
-	object, err := getResourceFromObj(componentWorkload, cli, ns, util.MergeMapOverrideWithDst(map[string]string{
-		oam.LabelOAMResourceType: oam.ResourceTypeWorkload,
-	}, commonLabels), "")
-	if err != nil {
-		return nil, err
-	}
-	root[OutputFieldName] = object
+	requests := []resourceRequest{{
+		fieldKey: OutputFieldName,
+		obj:      componentWorkload,
+		labels: util.MergeMapOverrideWithDst(map[string]string{
+			oam.LabelOAMResourceType: oam.ResourceTypeWorkload,
+		}, commonLabels),
+	}}
 
 	for _, assist := range assists {
 		if assist.Type != AuxiliaryWorkload {
@@ -158,52 +227,92 @@ func (wd *workloadDef) getTemplateContext(ctx process.Context, cli client.Reader
 			return nil, err
 		}
 		// AuxiliaryWorkload will have a unique label("trait.oam.dev/resource"="name of outputs") in per component/app level
-		object, err := getResourceFromObj(traitRef, cli, ns, util.MergeMapOverrideWithDst(map[string]string{
-			oam.TraitTypeLabel: AuxiliaryWorkload,
-		}, commonLabels), assist.Name)
-		if err != nil {
-			return nil, err
-		}
-		root[OutputsFieldName] = map[string]interface{}{
-			assist.Name: object,
-		}
+		requests = append(requests, resourceRequest{
+			fieldKey:   OutputsFieldName,
+			outputName: assist.Name,
+			obj:        traitRef,
+			labels: util.MergeMapOverrideWithDst(map[string]string{
+				oam.TraitTypeLabel: AuxiliaryWorkload,
+			}, commonLabels),
+			traitResource: assist.Name,
+		})
+	}
+
+	root, err := wd.getContextBuilder(cli).build(ns, requests)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range rootLabels {
+		root[k] = v
 	}
 	return root, nil
 }
 
+// Render renders the workload's abstract template without mutating ctx
+func (wd *workloadDef) Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	return renderViaComplete(wd, ctx, abstractTemplate)
+}
+
+// Diff renders the workload's abstract template and diffs it against the cluster
+func (wd *workloadDef) Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	return diffViaRender(wd, ctx, cli, ns, abstractTemplate)
+}
+
 // HealthCheck address health check for workload
-func (wd *workloadDef) HealthCheck(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate string) (bool, error) {
+func (wd *workloadDef) HealthCheck(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate string) (HealthStatus, error) {
+	if wd.skipped {
+		return HealthStatusUnknown, &ErrDefinitionMissing{Name: wd.name}
+	}
 	if healthPolicyTemplate == "" {
-		return true, nil
+		return HealthStatusHealthy, nil
 	}
 	templateContext, err := wd.getTemplateContext(ctx, cli, ns)
 	if err != nil {
-		return false, errors.WithMessage(err, "get template context")
+		return HealthStatusUnknown, errors.WithMessage(err, "get template context")
+	}
+	healthy, err := checkHealth(templateContext, healthPolicyTemplate)
+	if err != nil {
+		return HealthStatusUnknown, err
 	}
-	return checkHealth(templateContext, healthPolicyTemplate)
+	if healthy {
+		return HealthStatusHealthy, nil
+	}
+	return HealthStatusUnhealthy, nil
 }
 
 func checkHealth(templateContext map[string]interface{}, healthPolicyTemplate string) (bool, error) {
+	healthy, _, err := evalHealthPolicy(templateContext, healthPolicyTemplate)
+	return healthy, err
+}
+
+// evalHealthPolicy evaluates healthPolicyTemplate and additionally returns
+// the optional HealthCheckReason field, used by traitDef.StatusWithDetails to
+// populate StatusDetails.Reason.
+func evalHealthPolicy(templateContext map[string]interface{}, healthPolicyTemplate string) (bool, string, error) {
 	bt, err := json.Marshal(templateContext)
 	if err != nil {
-		return false, errors.WithMessage(err, "json marshal template context")
+		return false, "", errors.WithMessage(err, "json marshal template context")
 	}
 
 	var buff = "context: " + string(bt) + "\n" + healthPolicyTemplate
 	var r cue.Runtime
 	inst, err := r.Compile("-", buff)
 	if err != nil {
-		return false, errors.WithMessage(err, "compile health template")
+		return false, "", errors.WithMessage(err, "compile health template")
 	}
 	healthy, err := inst.Lookup(HealthCheckPolicy).Bool()
 	if err != nil {
-		return false, errors.WithMessage(err, "evaluate health status")
+		return false, "", errors.WithMessage(err, "evaluate health status")
 	}
-	return healthy, nil
+	reason, _ := inst.Lookup(HealthCheckReason).String()
+	return healthy, reason, nil
 }
 
 // Status get workload status by customStatusTemplate
 func (wd *workloadDef) Status(ctx process.Context, cli client.Client, ns string, customStatusTemplate string) (string, error) {
+	if wd.skipped {
+		return "", &ErrDefinitionMissing{Name: wd.name}
+	}
 	if customStatusTemplate == "" {
 		return "", nil
 	}
@@ -232,15 +341,6 @@ type traitDef struct {
 	def
 }
 
-// NewTraitAbstractEngine create Trait Definition AbstractEngine
-func NewTraitAbstractEngine(name string) AbstractEngine {
-	return &traitDef{
-		def: def{
-			name: name,
-		},
-	}
-}
-
 // Params set definition's params
 func (td *traitDef) Params(params interface{}) AbstractEngine {
 	td.params = params
@@ -249,6 +349,9 @@ func (td *traitDef) Params(params interface{}) AbstractEngine {
 
 // Complete do trait definition's rendering
 func (td *traitDef) Complete(ctx process.Context, abstractTemplate string) error {
+	if err := td.checkTemplatePresent(abstractTemplate); err != nil {
+		return err
+	}
 	bi := build.NewContext().NewInstance("", nil)
 	if err := bi.AddFile("-", abstractTemplate); err != nil {
 		return err
@@ -273,7 +376,7 @@ func (td *traitDef) Complete(ctx process.Context, abstractTemplate string) error
 		processing := inst.Lookup("processing")
 		var err error
 		if processing.Exists() {
-			if inst, err = task.Process(inst); err != nil {
+			if inst, err = task.Process(inst, processingReader(ctx)); err != nil {
 				return errors.WithMessagef(err, "traitDef %s build", td.name)
 			}
 		}
@@ -319,10 +422,10 @@ func (td *traitDef) Complete(ctx process.Context, abstractTemplate string) error
 }
 
 func (td *traitDef) getTemplateContext(ctx process.Context, cli client.Reader, ns string) (map[string]interface{}, error) {
-	var root = map[string]interface{}{}
+	var rootLabels = map[string]interface{}{}
 	var commonLabels = map[string]string{}
 	for k, v := range ctx.BaseContextLabels() {
-		root[k] = v
+		rootLabels[k] = v
 		switch k {
 		case "appName":
 			commonLabels[oam.LabelAppName] = v
@@ -331,6 +434,7 @@ func (td *traitDef) getTemplateContext(ctx process.Context, cli client.Reader, n
 		}
 	}
 	_, assists := ctx.Output()
+	var requests []resourceRequest
 	for _, assist := range assists {
 		if assist.Type != td.name {
 			continue
@@ -339,26 +443,46 @@ func (td *traitDef) getTemplateContext(ctx process.Context, cli client.Reader, n
 		if err != nil {
 			return nil, err
 		}
-
-		object, err := getResourceFromObj(traitRef, cli, ns, util.MergeMapOverrideWithDst(map[string]string{
-			oam.TraitTypeLabel: assist.Type,
-		}, commonLabels), assist.Name)
-		if err != nil {
-			return nil, err
-		}
+		fieldKey := OutputFieldName
 		if assist.IsOutputs {
-			root[OutputsFieldName] = map[string]interface{}{
-				assist.Name: object,
-			}
-		} else {
-			root[OutputFieldName] = object
+			fieldKey = OutputsFieldName
 		}
+		requests = append(requests, resourceRequest{
+			fieldKey:   fieldKey,
+			outputName: assist.Name,
+			obj:        traitRef,
+			labels: util.MergeMapOverrideWithDst(map[string]string{
+				oam.TraitTypeLabel: assist.Type,
+			}, commonLabels),
+			traitResource: assist.Name,
+		})
+	}
+
+	root, err := td.getContextBuilder(cli).build(ns, requests)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range rootLabels {
+		root[k] = v
 	}
 	return root, nil
 }
 
+// Render renders the trait's abstract template without mutating ctx
+func (td *traitDef) Render(ctx process.Context, abstractTemplate string) ([]*unstructured.Unstructured, error) {
+	return renderViaComplete(td, ctx, abstractTemplate)
+}
+
+// Diff renders the trait's abstract template and diffs it against the cluster
+func (td *traitDef) Diff(ctx process.Context, cli client.Reader, ns string, abstractTemplate string) ([]ResourceDiff, error) {
+	return diffViaRender(td, ctx, cli, ns, abstractTemplate)
+}
+
 // Status get trait status by customStatusTemplate
 func (td *traitDef) Status(ctx process.Context, cli client.Client, ns string, customStatusTemplate string) (string, error) {
+	if td.skipped {
+		return "", &ErrDefinitionMissing{Name: td.name}
+	}
 	if customStatusTemplate == "" {
 		return "", nil
 	}
@@ -370,39 +494,23 @@ func (td *traitDef) Status(ctx process.Context, cli client.Client, ns string, cu
 }
 
 // HealthCheck address health check for trait
-func (td *traitDef) HealthCheck(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate string) (bool, error) {
+func (td *traitDef) HealthCheck(ctx process.Context, cli client.Client, ns string, healthPolicyTemplate string) (HealthStatus, error) {
+	if td.skipped {
+		return HealthStatusUnknown, &ErrDefinitionMissing{Name: td.name}
+	}
 	if healthPolicyTemplate == "" {
-		return true, nil
+		return HealthStatusHealthy, nil
 	}
 	templateContext, err := td.getTemplateContext(ctx, cli, ns)
 	if err != nil {
-		return false, errors.WithMessage(err, "get template context")
+		return HealthStatusUnknown, errors.WithMessage(err, "get template context")
 	}
-	return checkHealth(templateContext, healthPolicyTemplate)
-}
-
-func getResourceFromObj(obj *unstructured.Unstructured, client client.Reader, namespace string, labels map[string]string, outputsResource string) (map[string]interface{}, error) {
-	if outputsResource != "" {
-		labels[oam.TraitResource] = outputsResource
-	}
-	if obj.GetName() != "" {
-		u, err := util.GetObjectGivenGVKAndName(context.Background(), client, obj.GroupVersionKind(), namespace, obj.GetName())
-		if err != nil {
-			return nil, err
-		}
-		return u.Object, nil
-	}
-	list, err := util.GetObjectsGivenGVKAndLabels(context.Background(), client, obj.GroupVersionKind(), namespace, labels)
+	healthy, err := checkHealth(templateContext, healthPolicyTemplate)
 	if err != nil {
-		return nil, err
+		return HealthStatusUnknown, err
 	}
-	if len(list.Items) == 1 {
-		return list.Items[0].Object, nil
-	}
-	for _, v := range list.Items {
-		if v.GetLabels()[oam.TraitResource] == outputsResource {
-			return v.Object, nil
-		}
+	if healthy {
+		return HealthStatusHealthy, nil
 	}
-	return nil, errors.Errorf("no resources found gvk(%v) labels(%v)", obj.GroupVersionKind(), labels)
+	return HealthStatusUnhealthy, nil
 }
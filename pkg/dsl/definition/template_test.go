@@ -0,0 +1,63 @@
+package definition
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWorkloadDefSkippedDegradesNonBlocking covers the tri-state HealthCheck
+// degraded mode added for a missing WorkloadDefinition template: Complete
+// records the ErrDefinitionMissing and marks the engine skipped instead of
+// failing the whole render, and every later call on the same engine reports
+// HealthStatusUnknown/the same error rather than panicking on the
+// never-rendered output.
+func TestWorkloadDefSkippedDegradesNonBlocking(t *testing.T) {
+	wd := &workloadDef{def: def{name: "my-workload"}}
+
+	err := wd.Complete(&fakeContext{}, "   ")
+	require.Error(t, err)
+	var missing *ErrDefinitionMissing
+	require.True(t, errors.As(err, &missing))
+	assert.Equal(t, "my-workload", missing.Name)
+
+	status, err := wd.HealthCheck(nil, nil, "", "isHealth: true")
+	assert.Equal(t, HealthStatusUnknown, status)
+	require.True(t, errors.As(err, &missing))
+
+	msg, err := wd.Status(nil, nil, "", `message: "x"`)
+	assert.Empty(t, msg)
+	require.True(t, errors.As(err, &missing))
+}
+
+// TestTraitDefSkippedDegradesNonBlocking mirrors
+// TestWorkloadDefSkippedDegradesNonBlocking for traitDef.
+func TestTraitDefSkippedDegradesNonBlocking(t *testing.T) {
+	td := &traitDef{def: def{name: "my-trait"}}
+
+	err := td.Complete(&fakeContext{}, "")
+	require.Error(t, err)
+	var missing *ErrDefinitionMissing
+	require.True(t, errors.As(err, &missing))
+	assert.Equal(t, "my-trait", missing.Name)
+
+	status, err := td.HealthCheck(nil, nil, "", "isHealth: true")
+	assert.Equal(t, HealthStatusUnknown, status)
+	require.True(t, errors.As(err, &missing))
+
+	msg, err := td.Status(nil, nil, "", `message: "x"`)
+	assert.Empty(t, msg)
+	require.True(t, errors.As(err, &missing))
+}
+
+// TestHealthCheckNoPolicyDefaultsHealthy covers the non-skipped, no-policy
+// path: a Definition with no health policy template is treated as healthy
+// without ever needing a template context.
+func TestHealthCheckNoPolicyDefaultsHealthy(t *testing.T) {
+	wd := &workloadDef{def: def{name: "my-workload"}}
+	status, err := wd.HealthCheck(nil, nil, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, HealthStatusHealthy, status)
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	Register("http", httpTask)
+}
+
+type httpConfig struct {
+	Method  string                 `json:"method"`
+	URL     string                 `json:"url"`
+	Body    map[string]interface{} `json:"body"`
+	Headers map[string]string      `json:"headers"`
+}
+
+// httpTask issues an HTTP request described by `processing.http` and exposes
+// the decoded JSON response as `processing.http.response`, so it can be
+// referenced from `parameter`/`output`.
+func httpTask(ctx context.Context, reader client.Reader, value cue.Value) (interface{}, error) {
+	var cfg httpConfig
+	if err := value.Decode(&cfg); err != nil {
+		return nil, errors.WithMessage(err, "decode http task config")
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodGet
+	}
+
+	var body bytes.Reader
+	if cfg.Body != nil {
+		bt, err := json.Marshal(cfg.Body)
+		if err != nil {
+			return nil, errors.WithMessage(err, "marshal http task body")
+		}
+		body = *bytes.NewReader(bt)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, &body)
+	if err != nil {
+		return nil, errors.WithMessage(err, "build http task request")
+	}
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.WithMessage(err, "do http task request")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read http task response")
+	}
+
+	var response interface{}
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			response = string(respBody)
+		}
+	}
+	return map[string]interface{}{
+		"statusCode": resp.StatusCode,
+		"response":   response,
+	}, nil
+}
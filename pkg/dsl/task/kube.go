@@ -0,0 +1,58 @@
+package task
+
+import (
+	"context"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+func init() {
+	Register("kubeGet", kubeGetTask)
+}
+
+type kubeGetConfig struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Labels     map[string]string `json:"labels"`
+}
+
+// kubeGetTask fetches an existing cluster object described by
+// `processing.kubeGet`, by name when one is given or by labels otherwise,
+// exposing it as `processing.kubeGet.value`. It requires a Reader; without
+// one (e.g. rendering without cluster access, or until the calling
+// process.Context implements readerProvider — see pkg/dsl/definition) it
+// fails, the same as any other task that can't be evaluated.
+func kubeGetTask(ctx context.Context, reader client.Reader, value cue.Value) (interface{}, error) {
+	var cfg kubeGetConfig
+	if err := value.Decode(&cfg); err != nil {
+		return nil, errors.WithMessage(err, "decode kubeGet task config")
+	}
+	if reader == nil {
+		return nil, errors.New("kubeGet task requires a cluster reader")
+	}
+	gvk := schema.FromAPIVersionAndKind(cfg.APIVersion, cfg.Kind)
+
+	if cfg.Name != "" {
+		obj, err := util.GetObjectGivenGVKAndName(ctx, reader, gvk, cfg.Namespace, cfg.Name)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "get %s %s/%s", cfg.Kind, cfg.Namespace, cfg.Name)
+		}
+		return map[string]interface{}{"value": obj.Object}, nil
+	}
+
+	list, err := util.GetObjectsGivenGVKAndLabels(ctx, reader, gvk, cfg.Namespace, cfg.Labels)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "list %s in %s", cfg.Kind, cfg.Namespace)
+	}
+	if len(list.Items) == 0 {
+		return nil, errors.Errorf("no %s found in %s matching labels %v", cfg.Kind, cfg.Namespace, cfg.Labels)
+	}
+	return map[string]interface{}{"value": list.Items[0].Object}, nil
+}
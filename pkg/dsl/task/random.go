@@ -0,0 +1,41 @@
+package task
+
+import (
+	"context"
+	"crypto/rand"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	Register("randomPassword", randomPasswordTask)
+}
+
+const passwordAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+type randomPasswordConfig struct {
+	Length int `json:"length"`
+}
+
+// randomPasswordTask generates a random alphanumeric password, exposed as
+// `processing.randomPassword.value`, e.g. to seed a Secret's initial value.
+func randomPasswordTask(ctx context.Context, reader client.Reader, value cue.Value) (interface{}, error) {
+	var cfg randomPasswordConfig
+	if err := value.Decode(&cfg); err != nil {
+		return nil, errors.WithMessage(err, "decode randomPassword task config")
+	}
+	if cfg.Length <= 0 {
+		cfg.Length = 16
+	}
+
+	buf := make([]byte, cfg.Length)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, errors.WithMessage(err, "generate random password")
+	}
+	for i, b := range buf {
+		buf[i] = passwordAlphabet[int(b)%len(passwordAlphabet)]
+	}
+	return map[string]interface{}{"value": string(buf)}, nil
+}
@@ -0,0 +1,46 @@
+package task
+
+import (
+	"context"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	Register("secretGet", secretGetTask)
+}
+
+type secretGetConfig struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+}
+
+// secretGetTask reads one key out of an existing Secret, exposing it as
+// `processing.secretGet.value`, e.g. to pass a pre-provisioned credential
+// into `parameter`. Like kubeGet, it requires a Reader; without one (e.g.
+// until the calling process.Context implements readerProvider — see
+// pkg/dsl/definition) it fails.
+func secretGetTask(ctx context.Context, reader client.Reader, value cue.Value) (interface{}, error) {
+	var cfg secretGetConfig
+	if err := value.Decode(&cfg); err != nil {
+		return nil, errors.WithMessage(err, "decode secretGet task config")
+	}
+	if reader == nil {
+		return nil, errors.New("secretGet task requires a cluster reader")
+	}
+
+	var secret corev1.Secret
+	if err := reader.Get(ctx, apitypes.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}, &secret); err != nil {
+		return nil, errors.WithMessagef(err, "get secret %s/%s", cfg.Namespace, cfg.Name)
+	}
+	data, ok := secret.Data[cfg.Key]
+	if !ok {
+		return nil, errors.Errorf("secret %s/%s has no key %q", cfg.Namespace, cfg.Name, cfg.Key)
+	}
+	return map[string]interface{}{"value": string(data)}, nil
+}
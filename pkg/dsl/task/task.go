@@ -0,0 +1,58 @@
+// Package task implements the `processing` plugin pipeline that a
+// WorkloadDefinition/TraitDefinition's abstract template can use to pull
+// runtime data (an HTTP response, a cluster object, a generated secret) into
+// `parameter`/`output` rendering before the rest of the template is built.
+package task
+
+import (
+	"context"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TaskFn runs one `processing.<name>` block. value is the CUE value staged at
+// that path; the returned value is unified back into the instance at the
+// same path, so later lookups (e.g. `processing.kubeGet.value`) see the
+// result. reader is nil when the caller has no cluster access to offer.
+type TaskFn func(ctx context.Context, reader client.Reader, value cue.Value) (interface{}, error)
+
+var tasks = map[string]TaskFn{}
+
+// Register registers fn under name so Definitions can invoke it from a
+// `processing: { <name>: {...} }` block.
+func Register(name string, fn TaskFn) {
+	tasks[name] = fn
+}
+
+// Process evaluates every task registered under `processing` in inst and
+// fills each task's result back into inst at the same field path.
+func Process(inst *cue.Instance, reader client.Reader) (*cue.Instance, error) {
+	processing := inst.Lookup("processing")
+	if !processing.Exists() {
+		return inst, nil
+	}
+	st, err := processing.Struct()
+	if err != nil {
+		return nil, errors.WithMessage(err, "processing must be a struct")
+	}
+	for i := 0; i < st.Len(); i++ {
+		fieldInfo := st.Field(i)
+		if fieldInfo.IsDefinition || fieldInfo.IsHidden || fieldInfo.IsOptional {
+			continue
+		}
+		fn, ok := tasks[fieldInfo.Name]
+		if !ok {
+			return nil, errors.Errorf("processing task %q is not registered", fieldInfo.Name)
+		}
+		result, err := fn(context.Background(), reader, fieldInfo.Value)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "run processing task %q", fieldInfo.Name)
+		}
+		if inst, err = inst.Fill(result, "processing", fieldInfo.Name); err != nil {
+			return nil, errors.WithMessagef(err, "fill processing task %q result", fieldInfo.Name)
+		}
+	}
+	return inst, nil
+}
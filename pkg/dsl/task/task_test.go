@@ -0,0 +1,102 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compile(t *testing.T, doc string) *cue.Instance {
+	t.Helper()
+	var r cue.Runtime
+	inst, err := r.Compile("-", doc)
+	require.NoError(t, err)
+	return inst
+}
+
+func TestProcessUnregisteredTaskErrors(t *testing.T) {
+	inst := compile(t, `processing: notRegistered: {}`)
+	_, err := Process(inst, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"notRegistered" is not registered`)
+}
+
+func TestProcessNoProcessingIsNoop(t *testing.T) {
+	inst := compile(t, `output: {}`)
+	out, err := Process(inst, nil)
+	require.NoError(t, err)
+	assert.Same(t, inst, out)
+}
+
+func TestProcessFillsTaskResult(t *testing.T) {
+	inst := compile(t, `processing: randomPassword: length: 8`)
+	out, err := Process(inst, nil)
+	require.NoError(t, err)
+	value, err := out.Lookup("processing", "randomPassword", "value").String()
+	require.NoError(t, err)
+	assert.Len(t, value, 8)
+}
+
+func TestKubeGetTaskRequiresReader(t *testing.T) {
+	inst := compile(t, `
+apiVersion: "v1"
+kind:       "ConfigMap"
+name:       "cm"
+namespace:  "default"
+`)
+	_, err := kubeGetTask(context.Background(), nil, inst.Value())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a cluster reader")
+}
+
+func TestSecretGetTaskRequiresReader(t *testing.T) {
+	inst := compile(t, `
+name:      "s"
+namespace: "default"
+key:       "k"
+`)
+	_, err := secretGetTask(context.Background(), nil, inst.Value())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a cluster reader")
+}
+
+func TestRandomPasswordTaskDefaultsLength(t *testing.T) {
+	inst := compile(t, `{}`)
+	result, err := randomPasswordTask(context.Background(), nil, inst.Value())
+	require.NoError(t, err)
+	value, ok := result.(map[string]interface{})["value"].(string)
+	require.True(t, ok)
+	assert.Len(t, value, 16)
+}
+
+func TestRandomPasswordTaskHonorsLength(t *testing.T) {
+	inst := compile(t, `length: 8`)
+	result, err := randomPasswordTask(context.Background(), nil, inst.Value())
+	require.NoError(t, err)
+	value, ok := result.(map[string]interface{})["value"].(string)
+	require.True(t, ok)
+	assert.Len(t, value, 8)
+}
+
+func TestHTTPTaskDecodesJSONResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	inst := compile(t, `url: "`+srv.URL+`"`)
+	result, err := httpTask(context.Background(), nil, inst.Value())
+	require.NoError(t, err)
+	m, ok := result.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, http.StatusOK, m["statusCode"])
+	resp, ok := m["response"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, true, resp["ok"])
+}